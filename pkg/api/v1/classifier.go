@@ -0,0 +1,108 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"time"
+
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+)
+
+// ThresholdPolicy configures the built-in threshold Classifier for a single dashboard tab.
+// A zero-valued field disables that rule.
+type ThresholdPolicy struct {
+	// FailingRatioThreshold reclassifies a FAIL tab as ACCEPTABLE once fewer than this fraction
+	// of its tests are failing, as parsed from the summarizer's status message (see failingRatio).
+	FailingRatioThreshold float64
+	// StaleAfter reclassifies a tab as STALE once its last update exceeds this age.
+	StaleAfter time.Duration
+	// FlakeCountThreshold reclassifies a tab as FLAKY once its proxy flake count (see flakeCount)
+	// exceeds this.
+	FlakeCountThreshold int32
+}
+
+// statusCountsRE extracts the "N/M" counts out of a summarizer status message such as
+// "1/7 tests are passing!" or "3/7 tests are failing!".
+var statusCountsRE = regexp.MustCompile(`(\d+)/(\d+) tests are (passing|failing)`)
+
+// NewThresholdClassifier returns a Classifier expressing a project's health policy as simple
+// thresholds, so a dashboard owner can reclassify tabs without changing how the summarizer itself
+// computes OverallStatus. policyFor supplies the thresholds for a given tab, typically read off
+// that tab's dashboard-tab config.
+func NewThresholdClassifier(policyFor func(*configpb.DashboardTab) ThresholdPolicy) Classifier {
+	return func(_ context.Context, tab *configpb.DashboardTab, ts *summarypb.DashboardTabSummary) (string, string, error) {
+		policy := policyFor(tab)
+
+		if policy.StaleAfter > 0 && time.Since(toTime(ts.GetLastUpdateTimestamp())) > policy.StaleAfter {
+			return "STALE", ts.GetStatus(), nil
+		}
+		if policy.FlakeCountThreshold > 0 && flakeCount(ts) > policy.FlakeCountThreshold {
+			return "FLAKY", ts.GetStatus(), nil
+		}
+		if policy.FailingRatioThreshold > 0 && ts.GetOverallStatus() == summarypb.DashboardTabSummary_FAIL {
+			if ratio := failingRatio(ts); ratio < policy.FailingRatioThreshold {
+				return "ACCEPTABLE", ts.GetStatus(), nil
+			}
+		}
+		return ts.GetOverallStatus().String(), ts.GetStatus(), nil
+	}
+}
+
+// failingCount best-effort parses the summarizer's "N/M tests are {passing,failing}!" status
+// message into (number of failing tests, total tests), normalizing away which bucket the message
+// happens to name. ok is false if the message doesn't match the expected shape.
+func failingCount(ts *summarypb.DashboardTabSummary) (failing, total int32, ok bool) {
+	m := statusCountsRE.FindStringSubmatch(ts.GetStatus())
+	if m == nil {
+		return 0, 0, false
+	}
+	n, errN := strconv.Atoi(m[1])
+	tot, errTotal := strconv.Atoi(m[2])
+	if errN != nil || errTotal != nil {
+		return 0, 0, false
+	}
+	if m[3] == "passing" {
+		return int32(tot - n), int32(tot), true
+	}
+	return int32(n), int32(tot), true
+}
+
+// failingRatio returns the fraction of failing tests per failingCount. It returns 1 (fully
+// failing) if the status message doesn't match the expected shape, so an unparseable status never
+// looks healthier than it might be.
+func failingRatio(ts *summarypb.DashboardTabSummary) float64 {
+	failing, total, ok := failingCount(ts)
+	if !ok || total == 0 {
+		return 1
+	}
+	return float64(failing) / float64(total)
+}
+
+// flakeCount is a proxy flake count: TestGrid's summary proto doesn't carry one directly, so this
+// reuses the failing-test count from the status message. It returns 0 if the message doesn't
+// match the expected shape, so an unparseable status never trips the threshold.
+func flakeCount(ts *summarypb.DashboardTabSummary) int32 {
+	failing, _, ok := failingCount(ts)
+	if !ok {
+		return 0
+	}
+	return failing
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func TestNilClassifierPreservesDefaultBehavior(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:    "Marco",
+					DashboardTabName: "polo-1",
+					Status:           "1/7 tests are passing!",
+					OverallStatus:    summarypb.DashboardTabSummary_FLAKY,
+					LatestGreen:      "Hulk",
+				},
+			},
+		},
+	}
+	req := &apipb.ListTabSummariesRequest{Dashboard: "marco"}
+
+	withoutOption := setupTestServer(t, config, nil, summaries)
+	gotDefault, err := withoutOption.ListTabSummaries(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error with default server: %v", err)
+	}
+
+	withNilOption := setupTestServer(t, config, nil, summaries)
+	WithClassifier(nil)(withNilOption)
+	gotExplicitNil, err := withNilOption.ListTabSummaries(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error with WithClassifier(nil): %v", err)
+	}
+
+	if diff := cmp.Diff(gotDefault, gotExplicitNil, protocmp.Transform()); diff != "" {
+		t.Errorf("WithClassifier(nil) changed behavior (-default +explicit-nil):\n%s", diff)
+	}
+	if got := gotDefault.GetTabSummaries()[0].GetOverallStatus(); got != "FLAKY" {
+		t.Errorf("got OverallStatus %q, want FLAKY", got)
+	}
+}
+
+func TestNilClassifierPreservesDefaultBehaviorForGetTabSummary(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:    "Marco",
+					DashboardTabName: "polo-1",
+					Status:           "1/7 tests are passing!",
+					OverallStatus:    summarypb.DashboardTabSummary_FLAKY,
+					LatestGreen:      "Hulk",
+				},
+			},
+		},
+	}
+	req := &apipb.GetTabSummaryRequest{Dashboard: "marco", Tab: "polo-1"}
+
+	withoutOption := setupTestServer(t, config, nil, summaries)
+	gotDefault, err := withoutOption.GetTabSummary(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error with default server: %v", err)
+	}
+
+	withNilOption := setupTestServer(t, config, nil, summaries)
+	WithClassifier(nil)(withNilOption)
+	gotExplicitNil, err := withNilOption.GetTabSummary(context.Background(), req)
+	if err != nil {
+		t.Fatalf("unexpected error with WithClassifier(nil): %v", err)
+	}
+
+	if diff := cmp.Diff(gotDefault, gotExplicitNil, protocmp.Transform()); diff != "" {
+		t.Errorf("WithClassifier(nil) changed behavior (-default +explicit-nil):\n%s", diff)
+	}
+	if got := gotDefault.GetTabSummary().GetOverallStatus(); got != "FLAKY" {
+		t.Errorf("got OverallStatus %q, want FLAKY", got)
+	}
+}
+
+func TestClassifierOverridesStatusForGetTabSummary(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:    "Marco",
+					DashboardTabName: "polo-1",
+					Status:           "1/7 tests are passing!",
+					OverallStatus:    summarypb.DashboardTabSummary_FLAKY,
+				},
+			},
+		},
+	}
+	server := setupTestServer(t, config, nil, summaries)
+	WithClassifier(func(_ context.Context, _ *configpb.DashboardTab, _ *summarypb.DashboardTabSummary) (string, string, error) {
+		return "OVERRIDDEN", "overridden detail", nil
+	})(server)
+
+	got, err := server.GetTabSummary(context.Background(), &apipb.GetTabSummaryRequest{Dashboard: "marco", Tab: "polo-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tab := got.GetTabSummary()
+	if tab.GetOverallStatus() != "OVERRIDDEN" {
+		t.Errorf("got OverallStatus %q, want OVERRIDDEN", tab.GetOverallStatus())
+	}
+	if tab.GetDetailedStatusMessage() != "overridden detail" {
+		t.Errorf("got DetailedStatusMessage %q, want %q", tab.GetDetailedStatusMessage(), "overridden detail")
+	}
+}
+
+func TestClassifierOverridesStatus(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:    "Marco",
+					DashboardTabName: "polo-1",
+					Status:           "1/7 tests are passing!",
+					OverallStatus:    summarypb.DashboardTabSummary_FLAKY,
+				},
+			},
+		},
+	}
+	server := setupTestServer(t, config, nil, summaries)
+	WithClassifier(func(_ context.Context, _ *configpb.DashboardTab, _ *summarypb.DashboardTabSummary) (string, string, error) {
+		return "OVERRIDDEN", "overridden detail", nil
+	})(server)
+
+	got, err := server.ListTabSummaries(context.Background(), &apipb.ListTabSummariesRequest{Dashboard: "marco"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	tab := got.GetTabSummaries()[0]
+	if tab.GetOverallStatus() != "OVERRIDDEN" {
+		t.Errorf("got OverallStatus %q, want OVERRIDDEN", tab.GetOverallStatus())
+	}
+	if tab.GetDetailedStatusMessage() != "overridden detail" {
+		t.Errorf("got DetailedStatusMessage %q, want %q", tab.GetDetailedStatusMessage(), "overridden detail")
+	}
+}
+
+func TestThresholdClassifier(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy ThresholdPolicy
+		ts     *summarypb.DashboardTabSummary
+		want   string
+	}{
+		{
+			name:   "low failing ratio becomes acceptable",
+			policy: ThresholdPolicy{FailingRatioThreshold: 0.5},
+			ts: &summarypb.DashboardTabSummary{
+				Status:        "1/7 tests are failing!",
+				OverallStatus: summarypb.DashboardTabSummary_FAIL,
+			},
+			want: "ACCEPTABLE",
+		},
+		{
+			name:   "high failing ratio stays failing",
+			policy: ThresholdPolicy{FailingRatioThreshold: 0.1},
+			ts: &summarypb.DashboardTabSummary{
+				Status:        "5/7 tests are failing!",
+				OverallStatus: summarypb.DashboardTabSummary_FAIL,
+			},
+			want: "FAIL",
+		},
+		{
+			name:   "stale update becomes stale",
+			policy: ThresholdPolicy{StaleAfter: time.Hour},
+			ts: &summarypb.DashboardTabSummary{
+				OverallStatus:       summarypb.DashboardTabSummary_PASS,
+				LastUpdateTimestamp: 0,
+			},
+			want: "STALE",
+		},
+		{
+			name:   "no thresholds leaves status untouched",
+			policy: ThresholdPolicy{},
+			ts: &summarypb.DashboardTabSummary{
+				OverallStatus: summarypb.DashboardTabSummary_PASS,
+			},
+			want: "PASS",
+		},
+		{
+			name:   "flake count over threshold promotes a passing tab to flaky",
+			policy: ThresholdPolicy{FlakeCountThreshold: 3},
+			ts: &summarypb.DashboardTabSummary{
+				Status:        "2/10 tests are passing!",
+				OverallStatus: summarypb.DashboardTabSummary_PASS,
+			},
+			want: "FLAKY",
+		},
+		{
+			name:   "flake count under threshold leaves status untouched",
+			policy: ThresholdPolicy{FlakeCountThreshold: 20},
+			ts: &summarypb.DashboardTabSummary{
+				Status:        "1/10 tests are passing!",
+				OverallStatus: summarypb.DashboardTabSummary_PASS,
+			},
+			want: "PASS",
+		},
+		{
+			// Regression test: flakeCount must read the failing count directly off a "...are
+			// failing!" message (3), not its complement against the total (10-3=7), which would
+			// wrongly exceed the threshold below.
+			name:   "flake count reads the failing count directly when the status names it",
+			policy: ThresholdPolicy{FlakeCountThreshold: 4},
+			ts: &summarypb.DashboardTabSummary{
+				Status:        "3/10 tests are failing!",
+				OverallStatus: summarypb.DashboardTabSummary_PASS,
+			},
+			want: "PASS",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			classify := NewThresholdClassifier(func(*configpb.DashboardTab) ThresholdPolicy { return tc.policy })
+			status, _, err := classify(context.Background(), nil, tc.ts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if status != tc.want {
+				t.Errorf("got status %q, want %q", status, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
+	"github.com/gorilla/mux"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// Route registers the v1 API's HTTP handlers against server, optionally rooted under prefix.
+func Route(prefix *string, server *Server) *mux.Router {
+	router := mux.NewRouter()
+	parent := router.NewRoute().Subrouter()
+	if prefix != nil {
+		parent = parent.PathPrefix(*prefix).Subrouter()
+	}
+	parent.HandleFunc("/dashboards/{dashboard}/tab-summaries", server.ListTabSummariesHTTP).Methods(http.MethodGet)
+	parent.HandleFunc("/dashboards/{dashboard}/tab-summaries/{tab}", server.GetTabSummaryHTTP).Methods(http.MethodGet)
+	parent.HandleFunc("/dashboards/{dashboard}/tab-summaries:watch", server.WatchTabSummariesHTTP).Methods(http.MethodGet)
+	return router
+}
+
+func (s *Server) ListTabSummariesHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := &apipb.ListTabSummariesRequest{
+		Dashboard: mux.Vars(r)["dashboard"],
+		Scope:     q.Get("scope"),
+		Filter:    q.Get("filter"),
+		OrderBy:   q.Get("order_by"),
+		PageToken: q.Get("page_token"),
+	}
+	if raw := q.Get("page_size"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			http.Error(w, "invalid page_size", http.StatusBadRequest)
+			return
+		}
+		req.PageSize = int32(n)
+	}
+	resp, err := s.ListTabSummaries(r.Context(), req)
+	writeProtoJSON(w, resp, err)
+}
+
+func (s *Server) GetTabSummaryHTTP(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	req := &apipb.GetTabSummaryRequest{
+		Dashboard: vars["dashboard"],
+		Tab:       vars["tab"],
+		Scope:     r.URL.Query().Get("scope"),
+	}
+	resp, err := s.GetTabSummary(r.Context(), req)
+	writeProtoJSON(w, resp, err)
+}
+
+// WatchTabSummariesHTTP streams the same deltas as WatchTabSummaries, but as server-sent events
+// so browser clients can consume it without a gRPC-Web proxy. Each event's data is one
+// protojson-encoded WatchTabSummariesResponse.
+func (s *Server) WatchTabSummariesHTTP(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	req := &apipb.ListTabSummariesRequest{
+		Dashboard: mux.Vars(r)["dashboard"],
+		Scope:     q.Get("scope"),
+		Filter:    q.Get("filter"),
+		OrderBy:   q.Get("order_by"),
+	}
+
+	ctx := r.Context()
+	prev, err := s.watchSnapshot(ctx, req)
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpStatusFromCode(status.Code(err)))
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming unsupported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeSSEEvent(w, &apipb.WatchTabSummariesResponse{Deltas: snapshotDeltas(prev)}); err != nil {
+		return
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(s.watchInterval())
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			cur, err := s.watchSnapshot(ctx, req)
+			if err != nil {
+				return
+			}
+			deltas := diffTabSummaries(prev, cur)
+			prev = cur
+			if len(deltas) == 0 {
+				continue
+			}
+			if err := writeSSEEvent(w, &apipb.WatchTabSummariesResponse{Deltas: deltas}); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeSSEEvent(w http.ResponseWriter, msg proto.Message) error {
+	buf, err := protojson.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "data: %s\n\n", buf)
+	return err
+}
+
+// writeProtoJSON writes msg as protojson, or maps err to an HTTP status and writes it instead.
+func writeProtoJSON(w http.ResponseWriter, msg proto.Message, err error) {
+	if err != nil {
+		http.Error(w, status.Convert(err).Message(), httpStatusFromCode(status.Code(err)))
+		return
+	}
+	buf, err := protojson.Marshal(msg)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(buf)
+}
+
+func httpStatusFromCode(code codes.Code) int {
+	switch code {
+	case codes.OK:
+		return http.StatusOK
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.InvalidArgument:
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,151 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package v1 implements the v1 TestGrid API.
+package v1
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+	"google.golang.org/protobuf/proto"
+)
+
+// Opener reads the raw bytes stored at path, e.g. a GCS object.
+type Opener interface {
+	Open(ctx context.Context, path string) ([]byte, error)
+}
+
+// Option configures optional Server behavior.
+type Option func(*Server)
+
+// Classifier decides the OverallStatus and DetailedStatusMessage reported for a tab, overriding
+// the summarizer's own verdict. tab is the tab's config entry (nil if the tab no longer appears
+// in config); ts is the raw summary the summarizer produced for it.
+type Classifier func(ctx context.Context, tab *configpb.DashboardTab, ts *summarypb.DashboardTabSummary) (status string, detail string, err error)
+
+// Server serves the v1 TestGrid API out of the objects reachable through Client.
+type Server struct {
+	Client       Opener
+	DefaultScope string
+
+	// Classify, if set, overrides the OverallStatus/DetailedStatusMessage of every tab summary
+	// served by ListTabSummaries and GetTabSummary. A nil Classify preserves the summarizer's
+	// own OverallStatus.String() verbatim.
+	Classify Classifier
+
+	// WatchInterval is how often WatchTabSummaries polls for changes. Zero uses defaultWatchInterval.
+	WatchInterval time.Duration
+}
+
+// NewServer returns a Server that reads configs and summaries through client, defaulting to
+// defaultScope when a request does not specify one.
+func NewServer(client Opener, defaultScope string, opts ...Option) *Server {
+	s := &Server{
+		Client:       client,
+		DefaultScope: defaultScope,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// WithClassifier registers a Classifier to run on every tab summary before it's returned. Passing
+// nil restores the default behavior of reporting the summarizer's own OverallStatus.
+func WithClassifier(c Classifier) Option {
+	return func(s *Server) {
+		s.Classify = c
+	}
+}
+
+// WithWatchInterval sets how often WatchTabSummaries polls for changes.
+func WithWatchInterval(d time.Duration) Option {
+	return func(s *Server) {
+		s.WatchInterval = d
+	}
+}
+
+// scope returns requested, falling back to the server's default scope when empty.
+func (s *Server) scope(requested string) string {
+	if requested != "" {
+		return requested
+	}
+	return s.DefaultScope
+}
+
+func (s *Server) readConfig(ctx context.Context, scope string) (*configpb.Configuration, error) {
+	buf, err := s.Client.Open(ctx, scope+"/config")
+	if err != nil {
+		return nil, fmt.Errorf("open config: %w", err)
+	}
+	var cfg configpb.Configuration
+	if err := proto.Unmarshal(buf, &cfg); err != nil {
+		return nil, fmt.Errorf("unmarshal config: %w", err)
+	}
+	return &cfg, nil
+}
+
+func (s *Server) readSummary(ctx context.Context, scope, dashboard string) (*summarypb.DashboardSummary, error) {
+	path := fmt.Sprintf("%s/summary/summary-%s", scope, strings.ToLower(dashboard))
+	buf, err := s.Client.Open(ctx, path)
+	if err != nil {
+		return nil, fmt.Errorf("open summary: %w", err)
+	}
+	var sum summarypb.DashboardSummary
+	if err := proto.Unmarshal(buf, &sum); err != nil {
+		return nil, fmt.Errorf("unmarshal summary: %w", err)
+	}
+	return &sum, nil
+}
+
+func findDashboard(cfg *configpb.Configuration, name string) *configpb.Dashboard {
+	for _, d := range cfg.GetDashboards() {
+		if strings.EqualFold(d.GetName(), name) {
+			return d
+		}
+	}
+	return nil
+}
+
+func findDashboardTab(dash *configpb.Dashboard, name string) *configpb.DashboardTab {
+	for _, t := range dash.GetDashboardTab() {
+		if strings.EqualFold(t.GetName(), name) {
+			return t
+		}
+	}
+	return nil
+}
+
+// tabsByName indexes a dashboard's tabs by lowercased name for quick lookup.
+func tabsByName(dash *configpb.Dashboard) map[string]*configpb.DashboardTab {
+	m := make(map[string]*configpb.DashboardTab, len(dash.GetDashboardTab()))
+	for _, t := range dash.GetDashboardTab() {
+		m[strings.ToLower(t.GetName())] = t
+	}
+	return m
+}
+
+// toTime converts a summary's float64 unix-seconds timestamp into a time.Time.
+func toTime(epoch float64) time.Time {
+	sec, frac := math.Modf(epoch)
+	return time.Unix(int64(sec), int64(math.Round(frac*1e9)))
+}
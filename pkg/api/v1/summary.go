@@ -0,0 +1,314 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultPageSize = 100
+	maxPageSize     = 500
+)
+
+// ListTabSummaries returns the tab summaries for a dashboard, filtered, sorted and paginated
+// according to the request.
+func (s *Server) ListTabSummaries(ctx context.Context, req *apipb.ListTabSummariesRequest) (*apipb.ListTabSummariesResponse, error) {
+	scope := s.scope(req.GetScope())
+	cfg, err := s.readConfig(ctx, scope)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading config: %v", err)
+	}
+	dash := findDashboard(cfg, req.GetDashboard())
+	if dash == nil {
+		return nil, status.Errorf(codes.NotFound, "dashboard %q not found", req.GetDashboard())
+	}
+	summary, err := s.readSummary(ctx, scope, dash.GetName())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "no summary for dashboard %q: %v", dash.GetName(), err)
+	}
+
+	match, err := parseFilter(req.GetFilter())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parsing filter: %v", err)
+	}
+	less, err := parseOrderBy(req.GetOrderBy())
+	if err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "parsing order_by: %v", err)
+	}
+
+	byName := tabsByName(dash)
+	tabs := make([]*apipb.TabSummary, 0, len(summary.GetTabSummaries()))
+	for _, ts := range summary.GetTabSummaries() {
+		tab, err := s.tabSummary(ctx, byName[strings.ToLower(ts.GetDashboardTabName())], ts)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "classifying tab %q: %v", ts.GetDashboardTabName(), err)
+		}
+		if match(tab) {
+			tabs = append(tabs, tab)
+		}
+	}
+	sort.SliceStable(tabs, func(i, j int) bool { return less(tabs[i], tabs[j]) })
+
+	queryHash := hashQuery(scope, dash.GetName(), req.GetFilter(), req.GetOrderBy())
+
+	start := 0
+	if tok := req.GetPageToken(); tok != "" {
+		pt, err := decodePageToken(tok)
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid page_token: %v", err)
+		}
+		if pt.QueryHash != queryHash {
+			return nil, status.Error(codes.InvalidArgument, "page_token does not match this dashboard/filter/order_by")
+		}
+		found := false
+		for i, tab := range tabs {
+			if tab.GetTabName() == pt.LastTab {
+				start = i + 1
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, status.Error(codes.InvalidArgument, "page_token's cursor is no longer in the result set")
+		}
+	}
+	if start > len(tabs) {
+		start = len(tabs)
+	}
+
+	pageSize := int(req.GetPageSize())
+	switch {
+	case pageSize <= 0:
+		pageSize = defaultPageSize
+	case pageSize > maxPageSize:
+		pageSize = maxPageSize
+	}
+
+	end := start + pageSize
+	if end > len(tabs) {
+		end = len(tabs)
+	}
+	page := tabs[start:end]
+
+	resp := &apipb.ListTabSummariesResponse{TabSummaries: page}
+	if end < len(tabs) {
+		tok, err := encodePageToken(pageToken{
+			LastTab:   page[len(page)-1].GetTabName(),
+			QueryHash: queryHash,
+		})
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "encoding page_token: %v", err)
+		}
+		resp.NextPageToken = tok
+	}
+	return resp, nil
+}
+
+// GetTabSummary returns the tab summary for a single dashboard tab.
+func (s *Server) GetTabSummary(ctx context.Context, req *apipb.GetTabSummaryRequest) (*apipb.GetTabSummaryResponse, error) {
+	scope := s.scope(req.GetScope())
+	cfg, err := s.readConfig(ctx, scope)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "reading config: %v", err)
+	}
+	dash := findDashboard(cfg, req.GetDashboard())
+	if dash == nil {
+		return nil, status.Errorf(codes.NotFound, "dashboard %q not found", req.GetDashboard())
+	}
+	tab := findDashboardTab(dash, req.GetTab())
+	if tab == nil {
+		return nil, status.Errorf(codes.NotFound, "tab %q not found on dashboard %q", req.GetTab(), dash.GetName())
+	}
+	summary, err := s.readSummary(ctx, scope, dash.GetName())
+	if err != nil {
+		return nil, status.Errorf(codes.NotFound, "no summary for dashboard %q: %v", dash.GetName(), err)
+	}
+	for _, ts := range summary.GetTabSummaries() {
+		if strings.EqualFold(ts.GetDashboardTabName(), tab.GetName()) {
+			out, err := s.tabSummary(ctx, tab, ts)
+			if err != nil {
+				return nil, status.Errorf(codes.Internal, "classifying tab %q: %v", tab.GetName(), err)
+			}
+			return &apipb.GetTabSummaryResponse{TabSummary: out}, nil
+		}
+	}
+	return nil, status.Errorf(codes.NotFound, "no summary for tab %q", tab.GetName())
+}
+
+// tabSummary converts a summarizer DashboardTabSummary into the public API shape, applying the
+// server's Classifier (if any) to decide the reported status.
+func (s *Server) tabSummary(ctx context.Context, tab *configpb.DashboardTab, ts *summarypb.DashboardTabSummary) (*apipb.TabSummary, error) {
+	overallStatus := ts.GetOverallStatus().String()
+	detail := ts.GetStatus()
+	if s.Classify != nil {
+		st, d, err := s.Classify(ctx, tab, ts)
+		if err != nil {
+			return nil, err
+		}
+		overallStatus, detail = st, d
+	}
+	return &apipb.TabSummary{
+		DashboardName:         ts.GetDashboardName(),
+		TabName:               ts.GetDashboardTabName(),
+		DetailedStatusMessage: detail,
+		OverallStatus:         overallStatus,
+		LatestPassingBuild:    ts.GetLatestGreen(),
+		LastRunTimestamp:      toTimestamp(ts.GetLastRunTimestamp()),
+		LastUpdateTimestamp:   toTimestamp(ts.GetLastUpdateTimestamp()),
+	}, nil
+}
+
+// toTimestamp converts a summary's float64 unix-seconds timestamp into a proto Timestamp.
+func toTimestamp(epoch float64) *timestamppb.Timestamp {
+	sec, frac := math.Modf(epoch)
+	return &timestamppb.Timestamp{
+		Seconds: int64(sec),
+		Nanos:   int32(math.Round(frac * 1e9)),
+	}
+}
+
+type tabFilter func(*apipb.TabSummary) bool
+
+// parseFilter parses a small predicate DSL: whitespace-separated clauses, ANDed together.
+// Supported clauses: "overall_status:A,B", "dashboard_tab_name:<glob>", "stale><duration>".
+func parseFilter(filter string) (tabFilter, error) {
+	filter = strings.TrimSpace(filter)
+	if filter == "" {
+		return func(*apipb.TabSummary) bool { return true }, nil
+	}
+	var preds []tabFilter
+	for _, clause := range strings.Fields(filter) {
+		pred, err := parseFilterClause(clause)
+		if err != nil {
+			return nil, fmt.Errorf("clause %q: %w", clause, err)
+		}
+		preds = append(preds, pred)
+	}
+	return func(ts *apipb.TabSummary) bool {
+		for _, pred := range preds {
+			if !pred(ts) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+func parseFilterClause(clause string) (tabFilter, error) {
+	switch {
+	case strings.HasPrefix(clause, "overall_status:"):
+		wanted := strings.Split(strings.TrimPrefix(clause, "overall_status:"), ",")
+		return func(ts *apipb.TabSummary) bool {
+			for _, w := range wanted {
+				if strings.EqualFold(ts.GetOverallStatus(), w) {
+					return true
+				}
+			}
+			return false
+		}, nil
+	case strings.HasPrefix(clause, "dashboard_tab_name:"):
+		glob := strings.TrimPrefix(clause, "dashboard_tab_name:")
+		return func(ts *apipb.TabSummary) bool {
+			ok, err := path.Match(glob, ts.GetTabName())
+			return err == nil && ok
+		}, nil
+	case strings.HasPrefix(clause, "stale>"):
+		age, err := time.ParseDuration(strings.TrimPrefix(clause, "stale>"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid staleness duration: %w", err)
+		}
+		return func(ts *apipb.TabSummary) bool {
+			last := ts.GetLastUpdateTimestamp()
+			if last == nil {
+				return false
+			}
+			return time.Since(last.AsTime()) > age
+		}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized filter clause %q", clause)
+	}
+}
+
+type tabLess func(a, b *apipb.TabSummary) bool
+
+// parseOrderBy parses the order_by request field: "name" (default), "status", or "last_run desc".
+func parseOrderBy(orderBy string) (tabLess, error) {
+	switch strings.TrimSpace(orderBy) {
+	case "", "name":
+		return func(a, b *apipb.TabSummary) bool { return a.GetTabName() < b.GetTabName() }, nil
+	case "status":
+		return func(a, b *apipb.TabSummary) bool { return a.GetOverallStatus() < b.GetOverallStatus() }, nil
+	case "last_run desc":
+		return func(a, b *apipb.TabSummary) bool {
+			return a.GetLastRunTimestamp().AsTime().After(b.GetLastRunTimestamp().AsTime())
+		}, nil
+	default:
+		return nil, fmt.Errorf("unrecognized order_by %q", orderBy)
+	}
+}
+
+// pageToken is the decoded form of a ListTabSummaries page_token. Encoding it opaquely (base64
+// JSON) keeps it an implementation detail; QueryHash ties a token to the query that produced it
+// so a token can't be replayed against a different dashboard, filter, or order_by.
+type pageToken struct {
+	LastTab   string `json:"last_tab"`
+	QueryHash string `json:"query_hash"`
+}
+
+func hashQuery(scope, dashboard, filter, orderBy string) string {
+	sum := sha256.Sum256([]byte(strings.Join([]string{scope, dashboard, filter, orderBy}, "\x00")))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func encodePageToken(pt pageToken) (string, error) {
+	buf, err := json.Marshal(pt)
+	if err != nil {
+		return "", err
+	}
+	// RawURLEncoding (no '+', '/', or padding) so the token survives being passed unescaped in a
+	// URL query parameter, matching the HTTP route's ?page_token=... usage.
+	return base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+func decodePageToken(tok string) (pageToken, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(tok)
+	if err != nil {
+		return pageToken{}, fmt.Errorf("decoding base64: %w", err)
+	}
+	var pt pageToken
+	if err := json.Unmarshal(buf, &pt); err != nil {
+		return pageToken{}, fmt.Errorf("unmarshaling token: %w", err)
+	}
+	return pt, nil
+}
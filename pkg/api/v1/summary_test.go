@@ -21,6 +21,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
 	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
@@ -240,7 +241,7 @@ func TestListTabSummaries(t *testing.T) {
 
 }
 
-func GetTabSummary(t *testing.T) {
+func TestGetTabSummary(t *testing.T) {
 	tests := []struct {
 		name        string
 		config      map[string]*configpb.Configuration
@@ -446,6 +447,285 @@ func GetTabSummary(t *testing.T) {
 
 }
 
+func TestListTabSummariesPagination(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+						{Name: "polo-2", TestGroupName: "tiramisu"},
+						{Name: "polo-3", TestGroupName: "donut"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{DashboardName: "Marco", DashboardTabName: "polo-1", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "polo-2", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "polo-3", OverallStatus: summarypb.DashboardTabSummary_PASS},
+			},
+		},
+	}
+
+	tests := []struct {
+		name        string
+		req         *apipb.ListTabSummariesRequest
+		wantTabs    []string
+		wantMore    bool
+		expectError bool
+	}{
+		{
+			name:     "default page size returns everything",
+			req:      &apipb.ListTabSummariesRequest{Dashboard: "marco"},
+			wantTabs: []string{"polo-1", "polo-2", "polo-3"},
+		},
+		{
+			name:     "page size smaller than result set returns a token",
+			req:      &apipb.ListTabSummariesRequest{Dashboard: "marco", PageSize: 2},
+			wantTabs: []string{"polo-1", "polo-2"},
+			wantMore: true,
+		},
+		{
+			name:     "page size smaller than result set returns one tab",
+			req:      &apipb.ListTabSummariesRequest{Dashboard: "marco", PageSize: 1},
+			wantTabs: []string{"polo-1"},
+			wantMore: true,
+		},
+		{
+			name:     "page size exactly matching result set has no token",
+			req:      &apipb.ListTabSummariesRequest{Dashboard: "marco", PageSize: 3},
+			wantTabs: []string{"polo-1", "polo-2", "polo-3"},
+			wantMore: false,
+		},
+		{
+			name:        "invalid page token is rejected",
+			req:         &apipb.ListTabSummariesRequest{Dashboard: "marco", PageToken: "not-valid-base64!!!"},
+			expectError: true,
+		},
+		{
+			name:        "unrecognized filter clause is rejected",
+			req:         &apipb.ListTabSummariesRequest{Dashboard: "marco", Filter: "bogus:thing"},
+			expectError: true,
+		},
+		{
+			name:        "unrecognized order_by is rejected",
+			req:         &apipb.ListTabSummariesRequest{Dashboard: "marco", OrderBy: "bogus"},
+			expectError: true,
+		},
+		{
+			name:     "empty page past the end",
+			req:      &apipb.ListTabSummariesRequest{Dashboard: "marco", PageSize: 10, PageToken: mustToken(t, "gs://default", "Marco", "", "", "polo-3")},
+			wantTabs: nil,
+		},
+		{
+			name: "page token minted for a different filter is rejected",
+			req: &apipb.ListTabSummariesRequest{
+				Dashboard: "marco",
+				Filter:    "overall_status:PASS",
+				// Minted against the unfiltered query, not "overall_status:PASS".
+				PageToken: mustToken(t, "gs://default", "Marco", "", "", "polo-1"),
+			},
+			expectError: true,
+		},
+		{
+			name: "page token minted for a different order_by is rejected",
+			req: &apipb.ListTabSummariesRequest{
+				Dashboard: "marco",
+				OrderBy:   "status",
+				PageToken: mustToken(t, "gs://default", "Marco", "", "", "polo-1"),
+			},
+			expectError: true,
+		},
+		{
+			name: "page token whose cursor tab is no longer in the result set is rejected",
+			req: &apipb.ListTabSummariesRequest{
+				Dashboard: "marco",
+				// polo-1 matches the token's query hash, but is filtered out of this result set.
+				Filter:    "dashboard_tab_name:polo-2",
+				PageToken: mustToken(t, "gs://default", "Marco", "dashboard_tab_name:polo-2", "", "polo-1"),
+			},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := setupTestServer(t, config, nil, summaries)
+			got, err := server.ListTabSummaries(context.Background(), tc.req)
+			if tc.expectError {
+				if err == nil {
+					t.Fatal("expected an error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var gotTabs []string
+			for _, ts := range got.GetTabSummaries() {
+				gotTabs = append(gotTabs, ts.GetTabName())
+			}
+			if diff := cmp.Diff(tc.wantTabs, gotTabs); diff != "" {
+				t.Errorf("unexpected tabs (-want +got):\n%s", diff)
+			}
+			if gotMore := got.GetNextPageToken() != ""; gotMore != tc.wantMore {
+				t.Errorf("got next_page_token present=%v, want %v", gotMore, tc.wantMore)
+			}
+		})
+	}
+}
+
+func TestListTabSummariesFilter(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+						{Name: "polo-2", TestGroupName: "tiramisu"},
+						{Name: "other-1", TestGroupName: "donut"},
+					},
+				},
+			},
+		},
+	}
+	now := time.Now()
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:       "Marco",
+					DashboardTabName:    "polo-1",
+					OverallStatus:       summarypb.DashboardTabSummary_FAIL,
+					LastUpdateTimestamp: float64(now.Unix()),
+				},
+				{
+					DashboardName:       "Marco",
+					DashboardTabName:    "polo-2",
+					OverallStatus:       summarypb.DashboardTabSummary_FLAKY,
+					LastUpdateTimestamp: float64(now.Add(-48 * time.Hour).Unix()),
+				},
+				{
+					DashboardName:       "Marco",
+					DashboardTabName:    "other-1",
+					OverallStatus:       summarypb.DashboardTabSummary_PASS,
+					LastUpdateTimestamp: float64(now.Unix()),
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		filter   string
+		wantTabs []string
+	}{
+		{
+			name:     "overall_status keeps only the matching statuses",
+			filter:   "overall_status:FAIL,FLAKY",
+			wantTabs: []string{"polo-1", "polo-2"},
+		},
+		{
+			name:     "dashboard_tab_name glob matches a prefix",
+			filter:   "dashboard_tab_name:polo-*",
+			wantTabs: []string{"polo-1", "polo-2"},
+		},
+		{
+			name:     "dashboard_tab_name glob excludes non-matching tabs",
+			filter:   "dashboard_tab_name:other-*",
+			wantTabs: []string{"other-1"},
+		},
+		{
+			name:     "stale keeps only tabs older than the duration",
+			filter:   "stale>24h",
+			wantTabs: []string{"polo-2"},
+		},
+		{
+			name:     "combined clauses are ANDed",
+			filter:   "overall_status:FAIL,FLAKY stale>24h",
+			wantTabs: []string{"polo-2"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := setupTestServer(t, config, nil, summaries)
+			got, err := server.ListTabSummaries(context.Background(), &apipb.ListTabSummariesRequest{
+				Dashboard: "marco",
+				Filter:    tc.filter,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			var gotTabs []string
+			for _, ts := range got.GetTabSummaries() {
+				gotTabs = append(gotTabs, ts.GetTabName())
+			}
+			if diff := cmp.Diff(tc.wantTabs, gotTabs); diff != "" {
+				t.Errorf("unexpected tabs (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestListTabSummariesStableOrdering(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "b-tab", TestGroupName: "a"},
+						{Name: "a-tab", TestGroupName: "b"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{DashboardName: "Marco", DashboardTabName: "b-tab", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "a-tab", OverallStatus: summarypb.DashboardTabSummary_FAIL},
+			},
+		},
+	}
+	server := setupTestServer(t, config, nil, summaries)
+
+	for i := 0; i < 5; i++ {
+		got, err := server.ListTabSummaries(context.Background(), &apipb.ListTabSummariesRequest{Dashboard: "marco"})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := []string{"a-tab", "b-tab"}
+		var gotTabs []string
+		for _, ts := range got.GetTabSummaries() {
+			gotTabs = append(gotTabs, ts.GetTabName())
+		}
+		if diff := cmp.Diff(want, gotTabs); diff != "" {
+			t.Errorf("run %d: unexpected order (-want +got):\n%s", i, diff)
+		}
+	}
+}
+
+func mustToken(t *testing.T, scope, dashboard, filter, orderBy, lastTab string) string {
+	t.Helper()
+	tok, err := encodePageToken(pageToken{
+		LastTab:   lastTab,
+		QueryHash: hashQuery(scope, dashboard, filter, orderBy),
+	})
+	if err != nil {
+		t.Fatalf("encoding token: %v", err)
+	}
+	return tok
+}
+
 func TestListTabSummariesHTTP(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -641,3 +921,201 @@ func TestListTabSummariesHTTP(t *testing.T) {
 		})
 	}
 }
+
+// TestListTabSummariesHTTPQueryParams exercises the page_size, page_token, filter and order_by
+// query params forwarded by ListTabSummariesHTTP, which TestListTabSummariesHTTP above doesn't
+// cover (it only varies scope).
+func TestListTabSummariesHTTPQueryParams(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+						{Name: "polo-2", TestGroupName: "tiramisu"},
+						{Name: "polo-3", TestGroupName: "gelato"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{DashboardName: "Marco", DashboardTabName: "polo-1", Status: "1/7 tests are passing!", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "polo-2", Status: "1/7 tests are failing!", OverallStatus: summarypb.DashboardTabSummary_FAIL},
+				{DashboardName: "Marco", DashboardTabName: "polo-3", Status: "1/7 tests are passing!", OverallStatus: summarypb.DashboardTabSummary_PASS},
+			},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		endpoint     string
+		wantTabs     []string
+		wantMore     bool
+		expectedCode int
+	}{
+		{
+			name:     "filter narrows the result set",
+			endpoint: "/dashboards/marco/tab-summaries?filter=overall_status:FAIL",
+			wantTabs: []string{"polo-2"},
+		},
+		{
+			name:     "order_by changes ordering",
+			endpoint: "/dashboards/marco/tab-summaries?order_by=status",
+			wantTabs: []string{"polo-2", "polo-1", "polo-3"},
+		},
+		{
+			name:     "page_size limits the page and reports more",
+			endpoint: "/dashboards/marco/tab-summaries?page_size=2",
+			wantTabs: []string{"polo-1", "polo-2"},
+			wantMore: true,
+		},
+		{
+			name:     "page_token resumes after the prior page",
+			endpoint: "/dashboards/marco/tab-summaries?page_size=2&page_token=" + mustToken(t, "gs://default", "Marco", "", "", "polo-2"),
+			wantTabs: []string{"polo-3"},
+		},
+		{
+			name:         "invalid page_size is rejected",
+			endpoint:     "/dashboards/marco/tab-summaries?page_size=nope",
+			expectedCode: http.StatusBadRequest,
+		},
+		{
+			name:         "unrecognized filter clause is rejected",
+			endpoint:     "/dashboards/marco/tab-summaries?filter=bogus:1",
+			expectedCode: http.StatusBadRequest,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := Route(nil, setupTestServer(t, config, nil, summaries))
+			request, err := http.NewRequest("GET", test.endpoint, nil)
+			if err != nil {
+				t.Fatalf("Can't form request: %v", err)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			wantCode := test.expectedCode
+			if wantCode == 0 {
+				wantCode = http.StatusOK
+			}
+			if response.Code != wantCode {
+				t.Fatalf("Expected %d, but got %d: %s", wantCode, response.Code, response.Body.String())
+			}
+			if wantCode != http.StatusOK {
+				return
+			}
+
+			var got apipb.ListTabSummariesResponse
+			if err := protojson.Unmarshal(response.Body.Bytes(), &got); err != nil {
+				t.Fatalf("Failed to unmarshal json message into a proto message: %v", err)
+			}
+			var gotTabs []string
+			for _, ts := range got.GetTabSummaries() {
+				gotTabs = append(gotTabs, ts.GetTabName())
+			}
+			if diff := cmp.Diff(test.wantTabs, gotTabs); diff != "" {
+				t.Errorf("got unexpected tab names (-want +got):\n%s", diff)
+			}
+			if (got.GetNextPageToken() != "") != test.wantMore {
+				t.Errorf("got NextPageToken %q, want present=%v", got.GetNextPageToken(), test.wantMore)
+			}
+		})
+	}
+}
+
+func TestGetTabSummaryHTTP(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]*configpb.Configuration
+		summaries    map[string]*summarypb.DashboardSummary
+		endpoint     string
+		expectedCode int
+		want         *apipb.GetTabSummaryResponse
+	}{
+		{
+			name: "Returns an error when there's no dashboard in config",
+			config: map[string]*configpb.Configuration{
+				"gs://default/config": {},
+			},
+			endpoint:     "/dashboards/whatever/tab-summaries/whatever",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "Returns correct tab summary for a dashboard-tab",
+			config: map[string]*configpb.Configuration{
+				"gs://default/config": {
+					Dashboards: []*configpb.Dashboard{
+						{
+							Name: "Marco",
+							DashboardTab: []*configpb.DashboardTab{
+								{Name: "polo-1", TestGroupName: "cheesecake"},
+							},
+						},
+					},
+				},
+			},
+			summaries: map[string]*summarypb.DashboardSummary{
+				"gs://default/summary/summary-marco": {
+					TabSummaries: []*summarypb.DashboardTabSummary{
+						{
+							DashboardName:       "Marco",
+							DashboardTabName:    "polo-1",
+							Status:              "1/7 tests are passing!",
+							OverallStatus:       summarypb.DashboardTabSummary_FLAKY,
+							LatestGreen:         "Hulk",
+							LastUpdateTimestamp: float64(915166800),
+							LastRunTimestamp:    float64(915166800),
+						},
+					},
+				},
+			},
+			endpoint:     "/dashboards/marco/tab-summaries/polo-1",
+			expectedCode: http.StatusOK,
+			want: &apipb.GetTabSummaryResponse{
+				TabSummary: &apipb.TabSummary{
+					DashboardName:         "Marco",
+					TabName:               "polo-1",
+					OverallStatus:         "FLAKY",
+					DetailedStatusMessage: "1/7 tests are passing!",
+					LatestPassingBuild:    "Hulk",
+					LastUpdateTimestamp: &timestamp.Timestamp{
+						Seconds: 915166800,
+					},
+					LastRunTimestamp: &timestamp.Timestamp{
+						Seconds: 915166800,
+					},
+				},
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			router := Route(nil, setupTestServer(t, test.config, nil, test.summaries))
+			request, err := http.NewRequest("GET", test.endpoint, nil)
+			if err != nil {
+				t.Fatalf("Can't form request: %v", err)
+			}
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != test.expectedCode {
+				t.Errorf("Expected %d, but got %d", test.expectedCode, response.Code)
+			}
+
+			if response.Code == http.StatusOK {
+				var got apipb.GetTabSummaryResponse
+				if err := protojson.Unmarshal(response.Body.Bytes(), &got); err != nil {
+					t.Fatalf("Failed to unmarshal json message into a proto message: %v", err)
+				}
+				if diff := cmp.Diff(test.want, &got, protocmp.Transform()); diff != "" {
+					t.Errorf("Obtained unexpected  diff (-want +got):\n%s", diff)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	statepb "github.com/GoogleCloudPlatform/testgrid/pb/state"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+	"google.golang.org/protobuf/proto"
+)
+
+// fakeOpener is an in-memory Opener backed by marshaled proto messages, keyed by path. It's safe
+// for concurrent use so tests can mutate the backing store (e.g. to simulate a new summary
+// landing) while a Watch handler is polling it in another goroutine.
+type fakeOpener struct {
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+func newFakeOpener() *fakeOpener {
+	return &fakeOpener{objects: map[string][]byte{}}
+}
+
+func (f *fakeOpener) Open(_ context.Context, path string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	buf, ok := f.objects[path]
+	if !ok {
+		return nil, fmt.Errorf("no object at %q", path)
+	}
+	return buf, nil
+}
+
+func (f *fakeOpener) set(path string, buf []byte) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.objects[path] = buf
+}
+
+// setupTestServer builds a Server backed by the given configs, grid states and summaries, all
+// keyed by their object path (e.g. "gs://default/config").
+func setupTestServer(t *testing.T, configs map[string]*configpb.Configuration, grids map[string]*statepb.Grid, summaries map[string]*summarypb.DashboardSummary) *Server {
+	t.Helper()
+	opener := newFakeOpener()
+	for path, cfg := range configs {
+		if path == "gs://welp/config" {
+			// Simulate an unreadable/corrupt config object.
+			opener.set(path, []byte{0xff, 0xff, 0xff, 0xff, 0xff})
+			continue
+		}
+		buf, err := proto.Marshal(cfg)
+		if err != nil {
+			t.Fatalf("marshaling config %q: %v", path, err)
+		}
+		opener.set(path, buf)
+	}
+	for path, grid := range grids {
+		buf, err := proto.Marshal(grid)
+		if err != nil {
+			t.Fatalf("marshaling grid %q: %v", path, err)
+		}
+		opener.set(path, buf)
+	}
+	for path, sum := range summaries {
+		buf, err := proto.Marshal(sum)
+		if err != nil {
+			t.Fatalf("marshaling summary %q: %v", path, err)
+		}
+		opener.set(path, buf)
+	}
+	return NewServer(opener, "gs://default")
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+)
+
+// defaultWatchInterval is how often WatchTabSummaries polls the underlying summary storage when
+// the Server doesn't specify one.
+const defaultWatchInterval = 30 * time.Second
+
+// WatchTabSummariesStream is the server-streaming interface WatchTabSummaries sends deltas over;
+// it's satisfied by the generated gRPC server-streaming handle for the WatchTabSummaries RPC.
+type WatchTabSummariesStream interface {
+	Send(*apipb.WatchTabSummariesResponse) error
+	Context() context.Context
+}
+
+// WatchTabSummaries streams an initial snapshot of req's dashboard, then a coalesced delta
+// (added/updated/removed tabs) every time the polled summary changes, until the stream's context
+// is canceled.
+func (s *Server) WatchTabSummaries(req *apipb.ListTabSummariesRequest, stream WatchTabSummariesStream) error {
+	ctx := stream.Context()
+	interval := s.watchInterval()
+
+	prev, err := s.watchSnapshot(ctx, req)
+	if err != nil {
+		return err
+	}
+	if err := stream.Send(&apipb.WatchTabSummariesResponse{Deltas: snapshotDeltas(prev)}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			cur, err := s.watchSnapshot(ctx, req)
+			if err != nil {
+				return err
+			}
+			deltas := diffTabSummaries(prev, cur)
+			prev = cur
+			if len(deltas) == 0 {
+				continue
+			}
+			if err := stream.Send(&apipb.WatchTabSummariesResponse{Deltas: deltas}); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *Server) watchInterval() time.Duration {
+	if s.WatchInterval > 0 {
+		return s.WatchInterval
+	}
+	return defaultWatchInterval
+}
+
+// watchSnapshot polls ListTabSummaries to exhaustion (following NextPageToken) and indexes the
+// full result by tab name, so a dashboard with more tabs than one page doesn't silently lose
+// updates for everything past the first page.
+func (s *Server) watchSnapshot(ctx context.Context, req *apipb.ListTabSummariesRequest) (map[string]*apipb.TabSummary, error) {
+	snapshot := make(map[string]*apipb.TabSummary)
+	pageReq := proto.Clone(req).(*apipb.ListTabSummariesRequest)
+	for {
+		resp, err := s.ListTabSummaries(ctx, pageReq)
+		if err != nil {
+			return nil, err
+		}
+		for _, tab := range resp.GetTabSummaries() {
+			snapshot[tab.GetTabName()] = tab
+		}
+		if resp.GetNextPageToken() == "" {
+			break
+		}
+		pageReq.PageToken = resp.GetNextPageToken()
+	}
+	return snapshot, nil
+}
+
+func snapshotDeltas(snapshot map[string]*apipb.TabSummary) []*apipb.TabSummaryDelta {
+	var deltas []*apipb.TabSummaryDelta
+	for _, tab := range snapshot {
+		deltas = append(deltas, &apipb.TabSummaryDelta{
+			Type:            apipb.TabSummaryDelta_ADDED,
+			Tab:             tab,
+			ResourceVersion: resourceVersion(tab),
+		})
+	}
+	return deltas
+}
+
+// diffTabSummaries compares two named snapshots and returns one delta per tab that was added,
+// changed, or removed between them. A tab present in both with an unchanged ResourceVersion
+// produces no delta, which is how repeated polls coalesce into "nothing happened".
+func diffTabSummaries(prev, cur map[string]*apipb.TabSummary) []*apipb.TabSummaryDelta {
+	var deltas []*apipb.TabSummaryDelta
+	for name, tab := range cur {
+		old, ok := prev[name]
+		rv := resourceVersion(tab)
+		switch {
+		case !ok:
+			deltas = append(deltas, &apipb.TabSummaryDelta{Type: apipb.TabSummaryDelta_ADDED, Tab: tab, ResourceVersion: rv})
+		case resourceVersion(old) != rv:
+			deltas = append(deltas, &apipb.TabSummaryDelta{Type: apipb.TabSummaryDelta_UPDATED, Tab: tab, ResourceVersion: rv})
+		}
+	}
+	for name, old := range prev {
+		if _, ok := cur[name]; !ok {
+			deltas = append(deltas, &apipb.TabSummaryDelta{Type: apipb.TabSummaryDelta_REMOVED, Tab: old, ResourceVersion: resourceVersion(old)})
+		}
+	}
+	return deltas
+}
+
+// resourceVersion derives a stable version token for a tab summary from its content, so a poll
+// that reads back identical bytes doesn't look like a change.
+func resourceVersion(tab *apipb.TabSummary) string {
+	buf, err := protojson.Marshal(tab)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:8])
+}
@@ -0,0 +1,330 @@
+/*
+Copyright 2023 The TestGrid Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	apipb "github.com/GoogleCloudPlatform/testgrid/pb/api/v1"
+	configpb "github.com/GoogleCloudPlatform/testgrid/pb/config"
+	summarypb "github.com/GoogleCloudPlatform/testgrid/pb/summary"
+	"github.com/google/go-cmp/cmp"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/testing/protocmp"
+)
+
+func readSSEEvent(t *testing.T, body *bufio.Scanner) string {
+	t.Helper()
+	for body.Scan() {
+		line := body.Text()
+		if strings.HasPrefix(line, "data: ") {
+			return strings.TrimPrefix(line, "data: ")
+		}
+	}
+	t.Fatal("stream ended without an SSE event")
+	return ""
+}
+
+func TestWatchTabSummariesHTTP(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{
+					DashboardName:    "Marco",
+					DashboardTabName: "polo-1",
+					Status:           "1/7 tests are passing!",
+					OverallStatus:    summarypb.DashboardTabSummary_FLAKY,
+					LatestGreen:      "Hulk",
+				},
+			},
+		},
+	}
+	server := setupTestServer(t, config, nil, summaries)
+	server.WatchInterval = 10 * time.Millisecond
+
+	ts := httptest.NewServer(Route(nil, server))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/dashboards/marco/tab-summaries:watch")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("got status %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("got Content-Type %q, want text/event-stream", ct)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	event := readSSEEvent(t, scanner)
+
+	var watchResp apipb.WatchTabSummariesResponse
+	if err := protojson.Unmarshal([]byte(event), &watchResp); err != nil {
+		t.Fatalf("unmarshaling event: %v", err)
+	}
+	if len(watchResp.GetDeltas()) != 1 {
+		t.Fatalf("got %d deltas, want 1", len(watchResp.GetDeltas()))
+	}
+	delta := watchResp.GetDeltas()[0]
+	if delta.GetType() != apipb.TabSummaryDelta_ADDED {
+		t.Errorf("got delta type %v, want ADDED", delta.GetType())
+	}
+	if got, want := delta.GetTab().GetTabName(), "polo-1"; got != want {
+		t.Errorf("got tab name %q, want %q", got, want)
+	}
+	if delta.GetResourceVersion() == "" {
+		t.Error("got empty resource_version")
+	}
+}
+
+func TestWatchSnapshotPaginatesFully(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "a"},
+						{Name: "polo-2", TestGroupName: "b"},
+						{Name: "polo-3", TestGroupName: "c"},
+					},
+				},
+			},
+		},
+	}
+	summaries := map[string]*summarypb.DashboardSummary{
+		"gs://default/summary/summary-marco": {
+			TabSummaries: []*summarypb.DashboardTabSummary{
+				{DashboardName: "Marco", DashboardTabName: "polo-1", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "polo-2", OverallStatus: summarypb.DashboardTabSummary_PASS},
+				{DashboardName: "Marco", DashboardTabName: "polo-3", OverallStatus: summarypb.DashboardTabSummary_PASS},
+			},
+		},
+	}
+	server := setupTestServer(t, config, nil, summaries)
+
+	// A page_size smaller than the tab count forces watchSnapshot to follow NextPageToken.
+	snapshot, err := server.watchSnapshot(context.Background(), &apipb.ListTabSummariesRequest{Dashboard: "marco", PageSize: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, name := range []string{"polo-1", "polo-2", "polo-3"} {
+		if _, ok := snapshot[name]; !ok {
+			t.Errorf("missing tab %q from snapshot, got %d tabs", name, len(snapshot))
+		}
+	}
+}
+
+func TestDiffTabSummaries(t *testing.T) {
+	passing := &apipb.TabSummary{TabName: "polo-1", OverallStatus: "PASS"}
+	failing := &apipb.TabSummary{TabName: "polo-1", OverallStatus: "FAIL"}
+	other := &apipb.TabSummary{TabName: "polo-2", OverallStatus: "PASS"}
+
+	tests := []struct {
+		name string
+		prev map[string]*apipb.TabSummary
+		cur  map[string]*apipb.TabSummary
+		want []*apipb.TabSummaryDelta
+	}{
+		{
+			name: "unchanged poll emits no deltas",
+			prev: map[string]*apipb.TabSummary{"polo-1": passing},
+			cur:  map[string]*apipb.TabSummary{"polo-1": passing},
+			want: nil,
+		},
+		{
+			name: "changed resource version emits an updated delta",
+			prev: map[string]*apipb.TabSummary{"polo-1": passing},
+			cur:  map[string]*apipb.TabSummary{"polo-1": failing},
+			want: []*apipb.TabSummaryDelta{
+				{Type: apipb.TabSummaryDelta_UPDATED, Tab: failing, ResourceVersion: resourceVersion(failing)},
+			},
+		},
+		{
+			name: "new tab emits an added delta",
+			prev: map[string]*apipb.TabSummary{"polo-1": passing},
+			cur:  map[string]*apipb.TabSummary{"polo-1": passing, "polo-2": other},
+			want: []*apipb.TabSummaryDelta{
+				{Type: apipb.TabSummaryDelta_ADDED, Tab: other, ResourceVersion: resourceVersion(other)},
+			},
+		},
+		{
+			name: "disappearing tab emits a removed delta",
+			prev: map[string]*apipb.TabSummary{"polo-1": passing, "polo-2": other},
+			cur:  map[string]*apipb.TabSummary{"polo-1": passing},
+			want: []*apipb.TabSummaryDelta{
+				{Type: apipb.TabSummaryDelta_REMOVED, Tab: other, ResourceVersion: resourceVersion(other)},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := diffTabSummaries(tc.prev, tc.cur)
+			if diff := cmp.Diff(tc.want, got, protocmp.Transform()); diff != "" {
+				t.Errorf("unexpected deltas (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWatchTabSummariesHTTPUpdatesOnChange(t *testing.T) {
+	config := map[string]*configpb.Configuration{
+		"gs://default/config": {
+			Dashboards: []*configpb.Dashboard{
+				{
+					Name: "Marco",
+					DashboardTab: []*configpb.DashboardTab{
+						{Name: "polo-1", TestGroupName: "cheesecake"},
+					},
+				},
+			},
+		},
+	}
+	summaryPath := "gs://default/summary/summary-marco"
+	passing := &summarypb.DashboardSummary{
+		TabSummaries: []*summarypb.DashboardTabSummary{
+			{DashboardName: "Marco", DashboardTabName: "polo-1", Status: "7/7 tests are passing!", OverallStatus: summarypb.DashboardTabSummary_PASS},
+		},
+	}
+	failing := &summarypb.DashboardSummary{
+		TabSummaries: []*summarypb.DashboardTabSummary{
+			{DashboardName: "Marco", DashboardTabName: "polo-1", Status: "1/7 tests are failing!", OverallStatus: summarypb.DashboardTabSummary_FAIL},
+		},
+	}
+
+	server := setupTestServer(t, config, nil, map[string]*summarypb.DashboardSummary{summaryPath: passing})
+	server.WatchInterval = 10 * time.Millisecond
+
+	ts := httptest.NewServer(Route(nil, server))
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/dashboards/marco/tab-summaries:watch")
+	if err != nil {
+		t.Fatalf("GET failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	first := readSSEEvent(t, scanner)
+	var snapshot apipb.WatchTabSummariesResponse
+	if err := protojson.Unmarshal([]byte(first), &snapshot); err != nil {
+		t.Fatalf("unmarshaling snapshot event: %v", err)
+	}
+	if got := snapshot.GetDeltas()[0].GetType(); got != apipb.TabSummaryDelta_ADDED {
+		t.Fatalf("got initial delta type %v, want ADDED", got)
+	}
+
+	// Mutate the backing summary in place; the next poll should observe the change.
+	buf, err := proto.Marshal(failing)
+	if err != nil {
+		t.Fatalf("marshaling updated summary: %v", err)
+	}
+	server.Client.(*fakeOpener).set(summaryPath, buf)
+
+	update := readSSEEvent(t, scanner)
+	var updateResp apipb.WatchTabSummariesResponse
+	if err := protojson.Unmarshal([]byte(update), &updateResp); err != nil {
+		t.Fatalf("unmarshaling update event: %v", err)
+	}
+	if len(updateResp.GetDeltas()) != 1 {
+		t.Fatalf("got %d deltas, want 1", len(updateResp.GetDeltas()))
+	}
+	delta := updateResp.GetDeltas()[0]
+	if delta.GetType() != apipb.TabSummaryDelta_UPDATED {
+		t.Errorf("got delta type %v, want UPDATED", delta.GetType())
+	}
+	if got, want := delta.GetTab().GetOverallStatus(), "FAIL"; got != want {
+		t.Errorf("got updated overall_status %q, want %q", got, want)
+	}
+}
+
+func TestWatchTabSummariesHTTPErrors(t *testing.T) {
+	tests := []struct {
+		name         string
+		config       map[string]*configpb.Configuration
+		summaries    map[string]*summarypb.DashboardSummary
+		endpoint     string
+		expectedCode int
+	}{
+		{
+			name: "no dashboard in config",
+			config: map[string]*configpb.Configuration{
+				"gs://default/config": {},
+			},
+			endpoint:     "/dashboards/whatever/tab-summaries:watch",
+			expectedCode: http.StatusNotFound,
+		},
+		{
+			name: "no summary for dashboard yet",
+			config: map[string]*configpb.Configuration{
+				"gs://default/config": {
+					Dashboards: []*configpb.Dashboard{
+						{
+							Name: "ACME",
+							DashboardTab: []*configpb.DashboardTab{
+								{Name: "me-me", TestGroupName: "testgroupname"},
+							},
+						},
+					},
+				},
+			},
+			endpoint:     "/dashboards/acme/tab-summaries:watch",
+			expectedCode: http.StatusNotFound,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := setupTestServer(t, tc.config, nil, tc.summaries)
+			ts := httptest.NewServer(Route(nil, server))
+			defer ts.Close()
+
+			resp, err := http.Get(ts.URL + tc.endpoint)
+			if err != nil {
+				t.Fatalf("GET failed: %v", err)
+			}
+			defer resp.Body.Close()
+
+			if resp.StatusCode != tc.expectedCode {
+				t.Errorf("got status %d, want %d", resp.StatusCode, tc.expectedCode)
+			}
+		})
+	}
+}